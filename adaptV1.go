@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+)
+
+// errAborted is handed to a legacy TxnFn's inbound channel in place of the manager's real abort
+// reason, when the phase channel closes without ever delivering a decision (e.g. the manager
+// tore down before broadcasting).
+var errAborted = stderrors.New("manager: transaction aborted")
+
+// adaptV1 wraps a legacy TxnFn as a TxnFnV2 so it can run alongside native 2PC participants.
+// Legacy txns don't get a real vote; they auto-approve Prepare, then find out the outcome all
+// at once through the same inboundC/outboundC contract they always have. abortErr points at the
+// Manager's decided abort reason (e.g. ctx.Err() for a cancelled run); it's only read once the
+// phase is actually PhaseAbort, by which point run has already filled it in.
+func adaptV1(fn TxnFn, abortErr *error) TxnFnV2 {
+	return func(ctx context.Context, started *sync.WaitGroup, phaseC <-chan Phase, voteC chan<- error) {
+		legacyIn := make(chan error, 1)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			fn(ctx, started, legacyIn, voteC)
+		}()
+
+		// Auto-vote yes; the legacy fn has no say over Prepare
+		voteC <- nil
+
+		phase, ok := <-phaseC
+		switch {
+		case !ok:
+			legacyIn <- errAborted
+		case phase == PhaseAbort:
+			if abortErr != nil && *abortErr != nil {
+				legacyIn <- *abortErr
+			} else {
+				legacyIn <- errAborted
+			}
+		default:
+			legacyIn <- nil
+		}
+		close(legacyIn)
+
+		<-done
+	}
+}
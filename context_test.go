@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerAbortsLegacyTxnsOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var gotErr error
+	fn := func(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error) {
+		started.Done()
+		gotErr = <-inboundC
+		outboundC <- nil
+	}
+
+	m := New(fn)
+
+	err := m.RunContext(ctx, func(ctx context.Context) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if !stderrors.Is(err, context.Canceled) {
+		t.Fatalf("RunContext() = %v, want context.Canceled", err)
+	}
+
+	if !stderrors.Is(gotErr, context.Canceled) {
+		t.Fatalf("legacy txn saw %v, want context.Canceled", gotErr)
+	}
+}
+
+func TestWithTxnTimeoutExpiresParticipantContext(t *testing.T) {
+	done := make(chan struct{})
+
+	fn := func(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error) {
+		started.Done()
+		<-ctx.Done()
+		close(done)
+		outboundC <- nil
+	}
+
+	m := New(fn).WithOptions(WithTxnTimeout(time.Millisecond))
+
+	block := make(chan struct{})
+	go m.Run(func() error {
+		<-block
+		return nil
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("txn context was never cancelled by WithTxnTimeout")
+	}
+	close(block)
+}
@@ -0,0 +1,32 @@
+package manager
+
+// Event is published to OnEvent subscribers as the manager's lifecycle progresses, e.g. for
+// wiring up prometheus counters.
+type Event struct {
+	// Type identifies the lifecycle point the event was emitted for, e.g. "run.start",
+	// "run.commit", "run.abort"
+	Type string
+	// Err carries the associated error, if any (e.g. the error a run was aborted with)
+	Err error
+}
+
+// exportData is the type queued internally by bufferExporter; it's the same shape as Event,
+// kept distinct so the internal queue can evolve independently of the public event shape.
+type exportData = Event
+
+// Stats is a snapshot of Manager's export backpressure and run history
+type Stats struct {
+	// InFlight is the number of events currently being delivered to the OnEvent callback
+	InFlight int64
+	// Queued is the number of events buffered and waiting to be delivered
+	Queued int64
+	// Dropped is the number of events that were discarded because the buffer was full and
+	// the enqueuing context was cancelled before room freed up
+	Dropped int64
+	// TotalRuns is the number of times Run/RunContext has been called since the exporter was
+	// started by OnEvent, Stats or EnqueueExport; calls made before that are not counted
+	TotalRuns int64
+	// LastErr is the error returned by the most recent Run/RunContext call counted in
+	// TotalRuns, if any
+	LastErr error
+}
@@ -0,0 +1,183 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultExportBuffer is used when WithBuffer isn't provided
+const defaultExportBuffer = 64
+
+// bufferExporter decouples txn completion from consumer draining: events are queued onto a
+// persistent buffered channel (sized once via WithBuffer, reused across every Run) and
+// delivered to the OnEvent callback from a single dedicated goroutine, so the manager never
+// runs the callback itself. Once the buffer is full, enqueuing waits for room or for the
+// enqueuing context to be done (see EnqueueExport).
+type bufferExporter struct {
+	mux sync.Mutex
+
+	input   chan exportData
+	stopped atomic.Bool
+
+	onEvent func(Event)
+
+	inFlight  int64
+	dropped   int64
+	totalRuns int64
+	lastErr   error
+}
+
+func newBufferExporter(size int) *bufferExporter {
+	if size <= 0 {
+		size = defaultExportBuffer
+	}
+
+	e := &bufferExporter{input: make(chan exportData, size)}
+	go e.deliver()
+	return e
+}
+
+func (e *bufferExporter) deliver() {
+	for data := range e.input {
+		e.mux.Lock()
+		e.inFlight++
+		cb := e.onEvent
+		e.mux.Unlock()
+
+		if cb != nil {
+			cb(data)
+		}
+
+		e.mux.Lock()
+		e.inFlight--
+		e.mux.Unlock()
+	}
+}
+
+// EnqueueExport submits data for delivery to the OnEvent callback. If there's room it enqueues
+// immediately; otherwise it blocks until room frees up or ctx is done, in which case the event
+// is dropped and EnqueueExport returns false. Passing an already-cancelled ctx makes this a
+// fail-fast, non-blocking attempt; passing context.Background() makes it block indefinitely.
+func (e *bufferExporter) EnqueueExport(ctx context.Context, data Event) bool {
+	if e.stopped.Load() {
+		return false
+	}
+
+	select {
+	case e.input <- data:
+		return true
+	default:
+	}
+
+	select {
+	case e.input <- data:
+		return true
+	case <-ctx.Done():
+		e.mux.Lock()
+		e.dropped++
+		e.mux.Unlock()
+		return false
+	}
+}
+
+func (e *bufferExporter) recordRun(err error) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.totalRuns++
+	e.lastErr = err
+}
+
+func (e *bufferExporter) stats() Stats {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	return Stats{
+		InFlight:  e.inFlight,
+		Queued:    int64(len(e.input)),
+		Dropped:   e.dropped,
+		TotalRuns: e.totalRuns,
+		LastErr:   e.lastErr,
+	}
+}
+
+func (e *bufferExporter) stop() {
+	if e.stopped.CompareAndSwap(false, true) {
+		close(e.input)
+	}
+}
+
+// exp lazily creates the manager's exporter, honoring WithBuffer if it was applied. It's only
+// called from OnEvent/Stats/EnqueueExport - actually using the exporter - so a caller who never
+// touches those never pays for the delivery goroutine it starts.
+func (m *Manager) exp() *bufferExporter {
+	m.expMux.Lock()
+	defer m.expMux.Unlock()
+
+	if m.exporter == nil {
+		m.exporter = newBufferExporter(m.exportBuffer)
+	}
+
+	return m.exporter
+}
+
+// expIfStarted returns the manager's exporter without creating one, or nil if OnEvent/Stats/
+// EnqueueExport has never been called. Plain lifecycle events (emit, recordRun) go through this
+// so a caller who never asked for export never starts its delivery goroutine.
+func (m *Manager) expIfStarted() *bufferExporter {
+	m.expMux.Lock()
+	defer m.expMux.Unlock()
+
+	return m.exporter
+}
+
+// OnEvent registers fn to be called for every lifecycle Event the manager emits. Only one
+// callback is kept; calling OnEvent again replaces the previous one.
+func (m *Manager) OnEvent(fn func(Event)) {
+	exp := m.exp()
+
+	exp.mux.Lock()
+	defer exp.mux.Unlock()
+	exp.onEvent = fn
+}
+
+// Stats returns a snapshot of the manager's export backpressure and run history
+func (m *Manager) Stats() Stats {
+	return m.exp().stats()
+}
+
+// EnqueueExport submits a custom Event for delivery to the OnEvent callback, subject to the
+// same backpressure as the manager's own lifecycle events.
+func (m *Manager) EnqueueExport(ctx context.Context, data Event) bool {
+	return m.exp().EnqueueExport(ctx, data)
+}
+
+// Close stops the manager's export delivery goroutine, if one was ever started. It does not
+// close a log attached via WithTxnLog - the caller constructed that and owns its lifecycle.
+// Close is a no-op if OnEvent/Stats/EnqueueExport were never called; Run alone does not start
+// the exporter.
+func (m *Manager) Close() error {
+	m.expMux.Lock()
+	exp := m.exporter
+	m.expMux.Unlock()
+
+	if exp != nil {
+		exp.stop()
+	}
+
+	return nil
+}
+
+// emit publishes a lifecycle event. If the buffer is full it waits for room until ctx is done
+// and then drops the event; with a ctx that is never done (Run uses context.Background) a full
+// buffer blocks the run until room frees up. It's a no-op if the caller never started the exporter via
+// OnEvent/Stats/EnqueueExport - plain Run/RunContext callers never pay for a delivery goroutine
+// they didn't ask for.
+func (m *Manager) emit(ctx context.Context, eventType string, err error) {
+	exp := m.expIfStarted()
+	if exp == nil {
+		return
+	}
+
+	exp.EnqueueExport(ctx, Event{Type: eventType, Err: err})
+}
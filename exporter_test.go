@@ -0,0 +1,154 @@
+package manager
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerRunDoesNotStartExporterWithoutOnEventStatsOrEnqueueExport(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	m := New()
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+
+	// Give any stray goroutine a moment to actually start before we count
+	time.Sleep(10 * time.Millisecond)
+
+	after := runtime.NumGoroutine()
+	if after > before {
+		t.Fatalf("goroutine count grew from %d to %d; Run should not start the exporter "+
+			"unless OnEvent/Stats/EnqueueExport was used", before, after)
+	}
+}
+
+func TestManagerOnEventReceivesLifecycleEvents(t *testing.T) {
+	var mu sync.Mutex
+	var types []string
+
+	m := New()
+	m.OnEvent(func(e Event) {
+		mu.Lock()
+		types = append(types, e.Type)
+		mu.Unlock()
+	})
+
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+
+	// Delivery happens off the dedicated goroutine; give it a chance to drain.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(types)
+		mu.Unlock()
+		if n >= 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(types) < 2 || types[0] != "run.start" || types[1] != "run.commit" {
+		t.Fatalf("types = %v, want [run.start run.commit ...]", types)
+	}
+}
+
+func TestManagerStatsTracksTotalRunsAndLastErr(t *testing.T) {
+	m := New()
+	m.OnEvent(func(Event) {})
+
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if m.Stats().TotalRuns == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := m.Stats()
+	if stats.TotalRuns != 1 {
+		t.Fatalf("TotalRuns = %d, want 1", stats.TotalRuns)
+	}
+	if stats.LastErr != nil {
+		t.Fatalf("LastErr = %v, want nil", stats.LastErr)
+	}
+}
+
+func TestBufferExporterDropsWhenFullAndContextDone(t *testing.T) {
+	e := newBufferExporter(1)
+	defer e.stop()
+
+	// The delivery goroutine drains input even without a callback, so park it inside a
+	// blocking callback on "a"; "b" then fills the one-slot buffer with nothing draining it.
+	taken := make(chan struct{}, 1)
+	release := make(chan struct{})
+	defer close(release)
+	e.mux.Lock()
+	e.onEvent = func(Event) {
+		taken <- struct{}{}
+		<-release
+	}
+	e.mux.Unlock()
+
+	if !e.EnqueueExport(context.Background(), Event{Type: "a"}) {
+		t.Fatal("first EnqueueExport should have had room")
+	}
+	<-taken
+
+	if !e.EnqueueExport(context.Background(), Event{Type: "fill"}) {
+		t.Fatal("EnqueueExport should have had room for the buffered event")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if e.EnqueueExport(ctx, Event{Type: "b"}) {
+		t.Fatal("EnqueueExport should have dropped with a full buffer and a done context")
+	}
+
+	if got := e.stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestBufferExporterStopClosesInputAndIsIdempotent(t *testing.T) {
+	e := newBufferExporter(1)
+
+	e.stop()
+	e.stop() // must not panic (double-close)
+
+	if e.EnqueueExport(context.Background(), Event{Type: "a"}) {
+		t.Fatal("EnqueueExport after stop should report false")
+	}
+}
+
+func TestManagerCloseStopsExporterWithoutPanicking(t *testing.T) {
+	m := New()
+	m.OnEvent(func(Event) {})
+
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+}
+
+func TestManagerCloseIsNoOpWhenExporterNeverStarted(t *testing.T) {
+	m := New()
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,121 @@
+package manager
+
+import "fmt"
+
+// defaultGroup is the name of the anonymous group New populates from its variadic fns
+const defaultGroup = ""
+
+// runnableGroup is a named collection of txns that start together and that other groups can
+// depend on.
+type runnableGroup struct {
+	name string
+	deps []string
+
+	fns   []TxnFn
+	fnsV2 []TxnFnV2
+}
+
+// participants returns every txn in the group, legacy and native, as a single TxnFnV2 slice.
+// abortErr points at the Manager's decided abort reason; it's not yet set at this point,
+// but legacy participants won't look at it until the phase is actually broadcast, by which time
+// run has filled it in. See adaptV1.
+func (g *runnableGroup) participants(abortErr *error) []TxnFnV2 {
+	fns := make([]TxnFnV2, 0, len(g.fns)+len(g.fnsV2))
+	for _, fn := range g.fns {
+		fns = append(fns, adaptV1(fn, abortErr))
+	}
+
+	return append(fns, g.fnsV2...)
+}
+
+// group returns the named group, creating it (and recording its position in groupOrder) if
+// this is the first time it's been referenced.
+func (m *Manager) group(name string) *runnableGroup {
+	if m.groups == nil {
+		m.groups = make(map[string]*runnableGroup)
+	}
+
+	g, ok := m.groups[name]
+	if !ok {
+		g = &runnableGroup{name: name}
+		m.groups[name] = g
+		m.groupOrder = append(m.groupOrder, name)
+	}
+
+	return g
+}
+
+// AddToGroup registers fn into the named group, creating the group on first use. deps names
+// groups that must finish starting before this group is opened; Manager.run walks the
+// resulting DAG, opening groups in dependency order and tearing them down in reverse.
+func (m *Manager) AddToGroup(name string, fn TxnFn, deps ...string) {
+	g := m.group(name)
+	g.fns = append(g.fns, fn)
+	g.deps = mergeDeps(g.deps, deps)
+}
+
+func mergeDeps(existing, added []string) []string {
+	for _, dep := range added {
+		var found bool
+		for _, have := range existing {
+			if have == dep {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			existing = append(existing, dep)
+		}
+	}
+
+	return existing
+}
+
+// groupRunOrder topologically sorts groups by dependency, using groupOrder (registration
+// order) to break ties so the result is deterministic.
+func (m *Manager) groupRunOrder() (order []string, err error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(m.groupOrder))
+	order = make([]string, 0, len(m.groupOrder))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("manager: dependency cycle detected at group %q", name)
+		}
+
+		state[name] = visiting
+
+		g := m.groups[name]
+		for _, dep := range g.deps {
+			if _, ok := m.groups[dep]; !ok {
+				return fmt.Errorf("manager: group %q depends on unregistered group %q", name, dep)
+			}
+
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range m.groupOrder {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func noopTxn(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error) {
+}
+
+func TestGroupRunOrderRespectsDependencies(t *testing.T) {
+	m := New()
+	m.AddToGroup("posthooks", noopTxn, "main")
+	m.AddToGroup("main", noopTxn, "prehooks")
+	m.AddToGroup("prehooks", noopTxn)
+
+	order, err := m.groupRunOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{defaultGroup, "prehooks", "main", "posthooks"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestGroupRunOrderIsDeterministicForIndependentGroups(t *testing.T) {
+	m := New()
+	m.AddToGroup("b", noopTxn)
+	m.AddToGroup("a", noopTxn)
+
+	order, err := m.groupRunOrder()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No dependency between "b" and "a", so registration order (groupOrder) should win
+	want := []string{defaultGroup, "b", "a"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestGroupRunOrderDetectsCycles(t *testing.T) {
+	m := New()
+	m.AddToGroup("a", noopTxn, "b")
+	m.AddToGroup("b", noopTxn, "a")
+
+	if _, err := m.groupRunOrder(); err == nil {
+		t.Fatal("expected a cycle error, got nil")
+	}
+}
+
+func TestGroupRunOrderRejectsUnregisteredDependency(t *testing.T) {
+	m := New()
+	m.AddToGroup("main", noopTxn, "does-not-exist")
+
+	if _, err := m.groupRunOrder(); err == nil {
+		t.Fatal("expected an unregistered-dependency error, got nil")
+	}
+}
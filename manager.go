@@ -1,16 +1,25 @@
 package manager
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/hatchify/errors"
 	"github.com/hatchify/queue"
+
+	"github.com/hatchify/transaction-manager/manager/txlog"
 )
 
-// New will implement a new instance of transactions Manager
+// New will implement a new instance of transactions Manager. fns are registered as a single
+// anonymous group; use AddToGroup to build out additional, ordered groups.
 func New(fns ...TxnFn) *Manager {
 	var m Manager
-	m.fns = fns
+	m.groups = map[string]*runnableGroup{
+		defaultGroup: {name: defaultGroup, fns: fns},
+	}
+	m.groupOrder = []string{defaultGroup}
 	return &m
 }
 
@@ -18,126 +27,272 @@ func New(fns ...TxnFn) *Manager {
 type Manager struct {
 	mux sync.Mutex
 
-	fns []TxnFn
+	groups     map[string]*runnableGroup
+	groupOrder []string
 
 	q *queue.Queue
 
-	out chan error
-	ins []chan error
+	out      chan error
+	running  []*runningGroup
+	keys     []string
+	abortErr error
+
+	txnTimeout time.Duration
+	log        *txlog.Log
+
+	expMux       sync.Mutex
+	exporter     *bufferExporter
+	exportBuffer int
+}
+
+// runningGroup tracks the phase channels opened for a runnableGroup over the course of a
+// single run, so the manager can broadcast and collect acks group-by-group on teardown.
+type runningGroup struct {
+	group  *runnableGroup
+	phases []chan Phase
+}
+
+// RegisterV2 adds a native 2PC participant to the default group. Unlike the legacy TxnFns
+// passed to New, it casts a real vote on Prepare and can veto the commit before run() is even
+// called.
+func (m *Manager) RegisterV2(fn TxnFnV2) {
+	g := m.group(defaultGroup)
+	g.fnsV2 = append(g.fnsV2, fn)
+}
+
+// WithOptions applies the provided options to the manager and returns it for chaining
+func (m *Manager) WithOptions(opts ...Option) *Manager {
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
 // Run will call the provided run func from within the collection of transactions
 func (m *Manager) Run(run func() error) (err error) {
+	return m.RunContext(context.Background(), func(context.Context) error {
+		return run()
+	})
+}
+
+// RunContext behaves like Run, with the addition that callers can cancel an in-flight
+// run via ctx. When ctx is cancelled before run completes, its error is broadcast to
+// every inbound transaction in place of run's own error.
+func (m *Manager) RunContext(ctx context.Context, run func(ctx context.Context) error) (err error) {
 	// Acquire mutex lock
 	m.mux.Lock()
 	// Defer the release of mutex lock
 	defer m.mux.Unlock()
 	// Call internal run func
-	return m.run(run)
+	return m.run(ctx, run)
 }
 
-func (m *Manager) initRun() {
+func (m *Manager) totalParticipants() (n int) {
+	for _, name := range m.groupOrder {
+		g := m.groups[name]
+		n += len(g.fns) + len(g.fnsV2)
+	}
+
+	return
+}
+
+func (m *Manager) initRun(n int) {
 	// Set queue
-	m.q = queue.New(len(m.fns), 0)
-	// Initialize out channel
-	m.out = make(chan error, len(m.fns))
-	// Initialize inbound channel slice
-	m.ins = make([]chan error, 0, len(m.fns))
+	m.q = queue.New(n, 0)
+	// Initialize out channel, sized for a Prepare vote and a commit/abort ack per participant
+	m.out = make(chan error, n*2)
+	// Initialize running-group tracking slice
+	m.running = make([]*runningGroup, 0, len(m.groupOrder))
+	// Initialize the keys identifying each txn; they count Prepare votes and, when a log is
+	// attached, key its write-ahead log records
+	m.keys = make([]string, 0, n)
 }
 
-func (m *Manager) run(run func() error) (err error) {
+func (m *Manager) run(ctx context.Context, run func(ctx context.Context) error) (err error) {
 	// Defer teardown of manager
 	defer m.teardown()
 
+	defer func() {
+		if exp := m.expIfStarted(); exp != nil {
+			exp.recordRun(err)
+		}
+	}()
+
+	order, err := m.groupRunOrder()
+	if err != nil {
+		return err
+	}
+
 	// Initialize items needed for run
-	m.initRun()
+	m.initRun(m.totalParticipants())
+
+	m.emit(ctx, "run.start", nil)
 
-	// Open provided transaction functions
-	done := m.openTxns()
+	// Open provided transaction functions, respecting group dependency order
+	done := m.openTxns(ctx, order)
 
-	// Now that transactions have been initialized, call target function
-	err = run()
+	// Phase 1: collect every participant's Prepare vote before deciding the outcome
+	voteErrs := m.collectN(len(m.keys))
 
-	// Push error to inbound channels
-	m.pushErrorToInbounds(err)
+	// Now that transactions have been initialized, call target function. Race it against
+	// ctx so a cancellation doesn't leave us blocked on a run func that ignores it.
+	runErrs := make(chan error, 1)
+	go func() {
+		runErrs <- run(ctx)
+	}()
+
+	select {
+	case err = <-runErrs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		err = voteErrs.Err()
+	}
+
+	phase := PhaseCommit
+	if err != nil {
+		phase = PhaseAbort
+		m.abortErr = err
+	}
+
+	m.logOutcome(txlog.Prepared)
+
+	// Phase 2: broadcast the decision and collect commit/abort acks, group by group, in the
+	// reverse of the order groups were opened in - e.g. close the Kafka group before the DB
+	// group it depended on
+	ackErrs := m.broadcastPhase(phase)
 
 	// Wait for all transactions to finish
 	done.Wait()
 
+	if err != nil {
+		m.logOutcome(txlog.RolledBack)
+		m.emit(ctx, "run.abort", err)
+	} else {
+		m.logOutcome(txlog.Committed)
+		m.emit(ctx, "run.commit", nil)
+	}
+
 	// Since all transactions have ended, we can safely close outbound channel
 	close(m.out)
 
-	// Get errors outbound out channel
-	errs := m.newErrorsFromOutbound()
-
 	if err != nil {
-		// We encountered an error when calling our target function. There is no need to collect
-		// additional errors, return
+		// We encountered an error either from Prepare votes or from calling our target
+		// function. There is no need to collect additional errors, return
 		return
 	}
 
-	// Collect and combine any errors we encountered during the transaction close
-	return errs.Err()
+	// Collect and combine any errors we encountered while committing
+	return ackErrs.Err()
 }
 
-func (m *Manager) pushErrorToInbounds(err error) {
-	// Push error to all open transactions
-	for _, in := range m.ins {
-		// Push error to inbound channel
-		in <- err
-		// Close inbound channel
-		close(in)
+func (m *Manager) logOutcome(eventType txlog.EventType) {
+	if m.log == nil {
+		return
+	}
+
+	for _, key := range m.keys {
+		m.log.Append(eventType, key, nil)
 	}
 }
 
-func (m *Manager) newErrorsFromOutbound() (errs errors.ErrorList) {
-	// Even if we've already encountered an error. We want to allow the channel to clear to avoid
-	// any potential memory leaks.
-	for txnErr := range m.out {
-		errs.Push(txnErr)
+func (m *Manager) collectN(n int) (errs errors.ErrorList) {
+	for i := 0; i < n; i++ {
+		errs.Push(<-m.out)
 	}
 
 	return
 }
 
-func (m *Manager) openTxns() (done *sync.WaitGroup) {
-	var start, end sync.WaitGroup
-	// Set waitgroups
-	start.Add(len(m.fns))
-	end.Add(len(m.fns))
+// broadcastPhase sends the decision to every participant and collects their acks, walking
+// running groups in reverse so the most recently-opened group tears down first.
+func (m *Manager) broadcastPhase(phase Phase) (ackErrs errors.ErrorList) {
+	for i := len(m.running) - 1; i >= 0; i-- {
+		rg := m.running[i]
+
+		for _, p := range rg.phases {
+			p <- phase
+			close(p)
+		}
 
-	// Iterate through transaction functions
-	for _, fn := range m.fns {
-		// Create inbound channel by opening transaction
-		inCh := m.openTxn(fn, m.out, &start, &end)
-		// Append inbound channel to inbound transactions slice
-		m.ins = append(m.ins, inCh)
+		groupErrs := m.collectN(len(rg.phases))
+		ackErrs.Push(groupErrs.Err())
 	}
 
-	// Wait for all transactions to start
-	start.Wait()
+	return
+}
+
+func (m *Manager) openTxns(ctx context.Context, order []string) (done *sync.WaitGroup) {
+	var end sync.WaitGroup
+
+	for _, name := range order {
+		g := m.groups[name]
+		participants := g.participants(&m.abortErr)
+
+		var start sync.WaitGroup
+		start.Add(len(participants))
+		end.Add(len(participants))
+
+		rg := &runningGroup{group: g}
+		for i, fn := range participants {
+			key := fmt.Sprintf("%s/%d", name, i)
+			m.keys = append(m.keys, key)
+			if m.log != nil {
+				m.log.Append(txlog.Begin, key, nil)
+			}
+
+			phaseCh := m.openTxn(ctx, fn, m.out, &start, &end)
+			rg.phases = append(rg.phases, phaseCh)
+		}
+
+		// Wait for this group's txns to start before opening the next group that depends
+		// on it
+		start.Wait()
+
+		m.running = append(m.running, rg)
+	}
 
-	// Assign reference to done
 	done = &end
 	return
 }
 
-func (m *Manager) openTxn(fn TxnFn, out chan error, start, end *sync.WaitGroup) (in chan error) {
-	in = make(chan error, 1)
+func (m *Manager) openTxn(ctx context.Context, fn TxnFnV2, out chan error, start, end *sync.WaitGroup) (phaseC chan Phase) {
+	phaseC = make(chan Phase, 1)
+
+	// Derive the per-txn context, applying WithTxnTimeout's deadline when set
+	txnCtx, cancel := m.txnContext(ctx)
+
 	m.q.New(func() {
-		fn(start, in, out)
+		defer cancel()
+		fn(txnCtx, start, phaseC, out)
 		end.Done()
 	})
 
 	return
 }
 
+func (m *Manager) txnContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.txnTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, m.txnTimeout)
+}
+
 func (m *Manager) teardown() {
-	// Close queue
-	m.q.Close()
+	// Close queue, if initRun ever got far enough to open one - run can return before that
+	// (e.g. groupRunOrder failing on a cycle), in which case there's nothing to close
+	if m.q != nil {
+		m.q.Close()
+	}
 
 	// Set references to nil
 	m.q = nil
-	m.ins = nil
+	m.running = nil
 	m.out = nil
+	m.keys = nil
+	m.abortErr = nil
 }
@@ -0,0 +1,36 @@
+package txlog
+
+// EventType enumerates the lifecycle events recorded for a transaction.
+type EventType byte
+
+// Event types recorded across a transaction's lifetime.
+const (
+	Begin EventType = iota
+	Prepared
+	Committed
+	RolledBack
+)
+
+// String implements fmt.Stringer
+func (e EventType) String() string {
+	switch e {
+	case Begin:
+		return "Begin"
+	case Prepared:
+		return "Prepared"
+	case Committed:
+		return "Committed"
+	case RolledBack:
+		return "RolledBack"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a single record appended to the write-ahead log
+type Event struct {
+	Sequence  uint64
+	EventType EventType
+	Key       string
+	Value     []byte
+}
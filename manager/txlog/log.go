@@ -0,0 +1,188 @@
+package txlog
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// fieldSep separates the fields of a single record; keys must not contain it
+const fieldSep = "\t"
+
+// New opens (creating if necessary) the log file at path and starts its writer goroutine
+func New(path string) (*Log, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		path:   path,
+		f:      f,
+		w:      bufio.NewWriter(f),
+		events: make(chan Event, 256),
+		errs:   make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+
+	go l.writeLoop()
+	return l, nil
+}
+
+// Log persists a monotonically-increasing record of transaction lifecycle events to a file,
+// appending them from a dedicated writer goroutine so Append only waits on disk I/O once its
+// 256-event buffer is full.
+type Log struct {
+	mux sync.Mutex
+
+	path string
+	f    *os.File
+	w    *bufio.Writer
+
+	events chan Event
+	errs   chan error
+	done   chan struct{}
+
+	seq uint64
+
+	closeOnce sync.Once
+}
+
+// Path returns the file path backing this log
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Errors returns the channel write errors encountered by the writer goroutine are delivered on.
+// It is buffered by one and lossy: callers that don't drain it won't stall the writer.
+func (l *Log) Errors() <-chan error {
+	return l.errs
+}
+
+// Append queues an event to be written to the log and returns its assigned sequence number.
+// The write itself happens asynchronously on the log's writer goroutine.
+func (l *Log) Append(eventType EventType, key string, value []byte) uint64 {
+	seq := atomic.AddUint64(&l.seq, 1)
+	l.events <- Event{Sequence: seq, EventType: eventType, Key: key, Value: value}
+	return seq
+}
+
+// Rotate truncates the log once it reaches maxBytes, moving the existing contents aside to
+// path+".bak" and starting a fresh file. Callers that need the prior history should read the
+// backup before the next Rotate overwrites it.
+func (l *Log) Rotate(maxBytes int64) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	info, err := l.f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() < maxBytes {
+		return nil
+	}
+
+	if err = l.w.Flush(); err != nil {
+		return err
+	}
+
+	if err = l.f.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(l.path, l.path+".bak"); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	return nil
+}
+
+// Close stops the writer goroutine and closes the underlying file, flushing any buffered
+// events first.
+func (l *Log) Close() (err error) {
+	l.closeOnce.Do(func() {
+		close(l.events)
+		<-l.done
+
+		l.mux.Lock()
+		defer l.mux.Unlock()
+		err = l.f.Close()
+	})
+
+	return
+}
+
+func (l *Log) writeLoop() {
+	defer close(l.done)
+
+	for ev := range l.events {
+		if err := l.appendToFile(ev); err != nil {
+			l.pushErr(err)
+		}
+	}
+}
+
+func (l *Log) appendToFile(ev Event) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if _, err := l.w.WriteString(formatLine(ev)); err != nil {
+		return err
+	}
+
+	return l.w.Flush()
+}
+
+func (l *Log) pushErr(err error) {
+	select {
+	case l.errs <- err:
+	default:
+		// Errors channel is unread, drop rather than block the writer goroutine
+	}
+}
+
+func formatLine(ev Event) string {
+	return strings.Join([]string{
+		strconv.FormatUint(ev.Sequence, 10),
+		strconv.Itoa(int(ev.EventType)),
+		ev.Key,
+		base64.StdEncoding.EncodeToString(ev.Value),
+	}, fieldSep) + "\n"
+}
+
+func parseLine(line string) (ev Event, err error) {
+	parts := strings.Split(line, fieldSep)
+	if len(parts) != 4 {
+		return ev, fmt.Errorf("txlog: malformed record %q", line)
+	}
+
+	if ev.Sequence, err = strconv.ParseUint(parts[0], 10, 64); err != nil {
+		return ev, fmt.Errorf("txlog: invalid sequence in record %q: %w", line, err)
+	}
+
+	eventType, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ev, fmt.Errorf("txlog: invalid event type in record %q: %w", line, err)
+	}
+	ev.EventType = EventType(eventType)
+
+	if ev.Value, err = base64.StdEncoding.DecodeString(parts[3]); err != nil {
+		return ev, fmt.Errorf("txlog: invalid value in record %q: %w", line, err)
+	}
+
+	ev.Key = parts[2]
+	return ev, nil
+}
@@ -0,0 +1,75 @@
+package txlog
+
+import (
+	"bufio"
+	"os"
+)
+
+// PendingTxn describes a transaction that reached Prepared but was never resolved with a
+// Committed or RolledBack event, as found by Replay
+type PendingTxn struct {
+	Key   string
+	Value []byte
+}
+
+type txnState struct {
+	value    []byte
+	prepared bool
+	resolved bool
+}
+
+// Replay scans the log at path and returns every transaction that reached Prepared without a
+// matching Committed or RolledBack event, in the order they were first seen. A missing log
+// file is treated as an empty one rather than an error, since that's the expected state on a
+// first run.
+func Replay(path string) (pending []PendingTxn, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	var order []string
+	states := make(map[string]*txnState)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev Event
+		if ev, err = parseLine(scanner.Text()); err != nil {
+			return nil, err
+		}
+
+		st, ok := states[ev.Key]
+		if !ok {
+			st = &txnState{}
+			states[ev.Key] = st
+			order = append(order, ev.Key)
+		}
+
+		switch ev.EventType {
+		case Begin:
+			st.value = ev.Value
+		case Prepared:
+			st.prepared = true
+		case Committed, RolledBack:
+			st.resolved = true
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range order {
+		st := states[key]
+		if st.prepared && !st.resolved {
+			pending = append(pending, PendingTxn{Key: key, Value: st.value})
+		}
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,66 @@
+package txlog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeLog(t *testing.T, events []Event) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "txn.log")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create log: %v", err)
+	}
+	defer f.Close()
+
+	for _, ev := range events {
+		if _, err := f.WriteString(formatLine(ev)); err != nil {
+			t.Fatalf("write event: %v", err)
+		}
+	}
+
+	return path
+}
+
+func TestReplayReturnsPreparedButUnresolvedTxns(t *testing.T) {
+	path := writeLog(t, []Event{
+		{Sequence: 1, EventType: Begin, Key: "txn-a", Value: []byte("a")},
+		{Sequence: 2, EventType: Prepared, Key: "txn-a"},
+
+		{Sequence: 3, EventType: Begin, Key: "txn-b", Value: []byte("b")},
+		{Sequence: 4, EventType: Prepared, Key: "txn-b"},
+		{Sequence: 5, EventType: Committed, Key: "txn-b"},
+
+		{Sequence: 6, EventType: Begin, Key: "txn-c", Value: []byte("c")},
+		{Sequence: 7, EventType: Prepared, Key: "txn-c"},
+		{Sequence: 8, EventType: RolledBack, Key: "txn-c"},
+
+		{Sequence: 9, EventType: Begin, Key: "txn-d", Value: []byte("d")},
+	})
+
+	pending, err := Replay(path)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	want := []PendingTxn{{Key: "txn-a", Value: []byte("a")}}
+	if !reflect.DeepEqual(pending, want) {
+		t.Fatalf("pending = %+v, want %+v", pending, want)
+	}
+}
+
+func TestReplayMissingFileIsEmpty(t *testing.T) {
+	pending, err := Replay(filepath.Join(t.TempDir(), "does-not-exist.log"))
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("pending = %+v, want empty", pending)
+	}
+}
@@ -0,0 +1,13 @@
+package manager
+
+import "testing"
+
+func TestRunReturnsErrorInsteadOfPanicOnBadGroupDeps(t *testing.T) {
+	m := New()
+	m.AddToGroup("a", noopTxn, "does-not-exist")
+
+	err := m.Run(func() error { return nil })
+	if err == nil {
+		t.Fatal("expected an unregistered-dependency error, got nil")
+	}
+}
@@ -0,0 +1,35 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/hatchify/transaction-manager/manager/txlog"
+)
+
+// Option configures optional Manager behavior. Options are applied with Manager.WithOptions.
+type Option func(m *Manager)
+
+// WithTxnTimeout bounds how long an individual transaction will wait to be signalled before
+// its context is cancelled, independent of the Run/RunContext caller's own context.
+func WithTxnTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.txnTimeout = d
+	}
+}
+
+// WithBuffer sizes the manager's export buffer (see Manager.OnEvent / Manager.Stats). It only
+// has an effect if applied before the exporter is first used; the default is 64.
+func WithBuffer(n int) Option {
+	return func(m *Manager) {
+		m.exportBuffer = n
+	}
+}
+
+// WithTxnLog attaches a write-ahead log to the manager. Once attached, every Run/RunContext
+// records Begin/Prepared/Committed/RolledBack events for its txns, and Manager.Recover can
+// replay the log on startup to pick back up after a crash.
+func WithTxnLog(l *txlog.Log) Option {
+	return func(m *Manager) {
+		m.log = l
+	}
+}
@@ -0,0 +1,19 @@
+package manager
+
+// Phase represents a stage of the two-phase commit protocol Manager drives its
+// participants through.
+type Phase byte
+
+const (
+	// PhasePrepare is the implicit phase every participant starts in: it has voted but the
+	// manager has not yet decided whether to commit or abort.
+	PhasePrepare Phase = iota
+
+	// PhaseCommit is broadcast once every participant has voted yes on Prepare and the
+	// caller's run func returned a nil error.
+	PhaseCommit
+
+	// PhaseAbort is broadcast when any participant votes error on Prepare, or the caller's
+	// run func returns a non-nil error.
+	PhaseAbort
+)
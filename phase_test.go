@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+)
+
+// voteTxn casts the given vote on Prepare, then records whatever phase the manager decides.
+func voteTxn(vote error, got *Phase) TxnFnV2 {
+	return func(ctx context.Context, started *sync.WaitGroup, phaseC <-chan Phase, voteC chan<- error) {
+		started.Done()
+		voteC <- vote
+
+		*got = <-phaseC
+		voteC <- nil
+	}
+}
+
+func TestManagerCommitsWhenAllVotesAndRunFuncSucceed(t *testing.T) {
+	var p1, p2 Phase
+	m := New()
+	m.RegisterV2(voteTxn(nil, &p1))
+	m.RegisterV2(voteTxn(nil, &p2))
+
+	if err := m.Run(func() error { return nil }); err != nil {
+		t.Fatalf("Run() = %v, want nil", err)
+	}
+
+	if p1 != PhaseCommit || p2 != PhaseCommit {
+		t.Fatalf("phases = %v, %v, want both PhaseCommit", p1, p2)
+	}
+}
+
+func TestManagerAbortsWhenAParticipantVotesNo(t *testing.T) {
+	voteErr := stderrors.New("no thanks")
+
+	var p1, p2 Phase
+	m := New()
+	m.RegisterV2(voteTxn(voteErr, &p1))
+	m.RegisterV2(voteTxn(nil, &p2))
+
+	err := m.Run(func() error { return nil })
+	if !stderrors.Is(err, voteErr) {
+		t.Fatalf("Run() = %v, want %v", err, voteErr)
+	}
+
+	if p1 != PhaseAbort || p2 != PhaseAbort {
+		t.Fatalf("phases = %v, %v, want both PhaseAbort", p1, p2)
+	}
+}
+
+func TestManagerAbortsWhenRunFuncFails(t *testing.T) {
+	runErr := stderrors.New("run failed")
+
+	var p1 Phase
+	m := New()
+	m.RegisterV2(voteTxn(nil, &p1))
+
+	err := m.Run(func() error { return runErr })
+	if !stderrors.Is(err, runErr) {
+		t.Fatalf("Run() = %v, want %v", err, runErr)
+	}
+
+	if p1 != PhaseAbort {
+		t.Fatalf("phase = %v, want PhaseAbort", p1)
+	}
+}
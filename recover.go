@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hatchify/transaction-manager/manager/txlog"
+)
+
+// Recover replays the write-ahead log attached via WithTxnLog and, for every transaction that
+// reached Prepared without a terminal Committed/RolledBack event, re-runs exactly that
+// participant - not its whole group; groupmates that already committed or rolled back are left
+// alone - through the manager's normal 2PC flow so it can finish what it started before the
+// crash. It is a no-op if no log is attached or the log has nothing outstanding.
+func (m *Manager) Recover(ctx context.Context) (recovered bool, err error) {
+	if m.log == nil {
+		return false, nil
+	}
+
+	var pending []txlog.PendingTxn
+	if pending, err = txlog.Replay(m.log.Path()); err != nil {
+		return false, err
+	}
+
+	if len(pending) == 0 {
+		return false, nil
+	}
+
+	for _, p := range pending {
+		fn, fnV2, ok := m.pendingParticipant(p.Key)
+		if !ok {
+			// The group/index encoded in this txn's key isn't registered on this Manager
+			// instance; there's nothing to re-invoke it with.
+			continue
+		}
+
+		sub := &Manager{groupOrder: []string{defaultGroup}, log: m.log}
+		if fnV2 != nil {
+			sub.groups = map[string]*runnableGroup{defaultGroup: {name: defaultGroup, fnsV2: []TxnFnV2{fnV2}}}
+		} else {
+			sub.groups = map[string]*runnableGroup{defaultGroup: {name: defaultGroup, fns: []TxnFn{fn}}}
+		}
+
+		if runErr := sub.RunContext(ctx, func(context.Context) error { return nil }); runErr != nil {
+			err = runErr
+		}
+	}
+
+	return true, err
+}
+
+// pendingParticipant resolves key (the "group/index" encoding established in Manager.openTxns)
+// back to the single TxnFn or TxnFnV2 it identifies. Exactly one of fn/fnV2 is non-nil when ok
+// is true, matching how runnableGroup.participants orders legacy fns before native ones.
+func (m *Manager) pendingParticipant(key string) (fn TxnFn, fnV2 TxnFnV2, ok bool) {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return nil, nil, false
+	}
+
+	name, idxStr := key[:i], key[i+1:]
+
+	idx, err := strconv.Atoi(idxStr)
+	if err != nil || idx < 0 {
+		return nil, nil, false
+	}
+
+	g, ok := m.groups[name]
+	if !ok {
+		return nil, nil, false
+	}
+
+	if idx < len(g.fns) {
+		return g.fns[idx], nil, true
+	}
+
+	idx -= len(g.fns)
+	if idx < len(g.fnsV2) {
+		return nil, g.fnsV2[idx], true
+	}
+
+	return nil, nil, false
+}
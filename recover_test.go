@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hatchify/transaction-manager/manager/txlog"
+)
+
+func TestRecoverOnlyReplaysThePendingParticipant(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txn.log")
+
+	var calls [3]int
+	fn := func(i int) TxnFn {
+		return func(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error) {
+			started.Done()
+			calls[i]++
+			<-inboundC
+			outboundC <- nil
+		}
+	}
+
+	log, err := txlog.New(path)
+	if err != nil {
+		t.Fatalf("txlog.New: %v", err)
+	}
+
+	// Simulate a crash: fn0 and fn1 fully committed, fn2 was left Prepared.
+	log.Append(txlog.Begin, defaultGroup+"/0", nil)
+	log.Append(txlog.Prepared, defaultGroup+"/0", nil)
+	log.Append(txlog.Committed, defaultGroup+"/0", nil)
+
+	log.Append(txlog.Begin, defaultGroup+"/1", nil)
+	log.Append(txlog.Prepared, defaultGroup+"/1", nil)
+	log.Append(txlog.Committed, defaultGroup+"/1", nil)
+
+	log.Append(txlog.Begin, defaultGroup+"/2", nil)
+	log.Append(txlog.Prepared, defaultGroup+"/2", nil)
+	log.Close()
+
+	log2, err := txlog.New(path)
+	if err != nil {
+		t.Fatalf("txlog.New: %v", err)
+	}
+
+	m := New(fn(0), fn(1), fn(2)).WithOptions(WithTxnLog(log2))
+
+	recovered, err := m.Recover(context.Background())
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if !recovered {
+		t.Fatal("recovered = false, want true")
+	}
+
+	if calls[0] != 0 || calls[1] != 0 {
+		t.Fatalf("calls = %v, want fn0 and fn1 (already committed) left untouched", calls)
+	}
+	if calls[2] != 1 {
+		t.Fatalf("calls[2] = %d, want 1 (the pending participant replayed once)", calls[2])
+	}
+
+}
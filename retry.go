@@ -0,0 +1,133 @@
+package manager
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried, either by Retry (per-txn) or
+// Manager.RunWithRetry (whole run).
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. Values below 1 are
+	// treated as 1.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// Multiplier scales the backoff after each failed attempt.
+	Multiplier float64
+	// Jitter is the fraction (0-1) of random variance added to each backoff.
+	Jitter float64
+	// Classify reports whether err is transient and worth retrying. A nil Classify treats
+	// every non-nil error as transient.
+	Classify func(error) bool
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if p.Classify == nil {
+		return true
+	}
+
+	return p.Classify(err)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 0; i < attempt; i++ {
+		d = time.Duration(float64(d) * p.Multiplier)
+	}
+
+	if p.Jitter <= 0 {
+		return d
+	}
+
+	delta := float64(d) * p.Jitter
+	return d + time.Duration(rand.Float64()*2*delta-delta)
+}
+
+// Retry wraps fn so that its post-decision work (commit or roll back, depending on what the
+// manager actually decided) is retried in place when it fails with a transient error. It reads
+// the manager's real decision exactly once, then drives fn against that same decision up to
+// policy.MaxAttempts times, backing off between attempts, and reports exactly one outcome back
+// to the manager. The happy path - fn succeeding on its first attempt - invokes fn exactly
+// once. fn must be idempotent, since it may run more than once against the same decision.
+func Retry(fn TxnFn, policy RetryPolicy) TxnFn {
+	return func(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error) {
+		// Signal started before the manager has decided anything; this must happen exactly
+		// once, so it stays outside the retry loop below.
+		started.Done()
+
+		decision, ok := <-inboundC
+		if !ok {
+			decision = errAborted
+		}
+
+		attempts := policy.MaxAttempts
+		if attempts < 1 {
+			attempts = 1
+		}
+
+		var outcome error
+		for attempt := 0; attempt < attempts; attempt++ {
+			outcome = runAgainstDecision(ctx, fn, decision)
+			if !policy.shouldRetry(outcome) {
+				break
+			}
+
+			if attempt < attempts-1 {
+				time.Sleep(policy.backoff(attempt))
+			}
+		}
+
+		outboundC <- outcome
+	}
+}
+
+// runAgainstDecision drives one attempt of fn's post-decision logic. Real channels are
+// single-use, so fn is given a fresh started/inboundC/outboundC triple for this attempt, but
+// decision - the value fed through inboundC - is always the real one the manager already sent,
+// never a fabricated one.
+func runAgainstDecision(ctx context.Context, fn TxnFn, decision error) error {
+	var started sync.WaitGroup
+	started.Add(1)
+
+	inbound := make(chan error, 1)
+	inbound <- decision
+	close(inbound)
+
+	outbound := make(chan error, 1)
+
+	go fn(ctx, &started, inbound, outbound)
+	started.Wait()
+
+	return <-outbound
+}
+
+// RunWithRetry calls Run repeatedly until it succeeds or policy's attempts are exhausted,
+// retrying only errors policy classifies as transient. Between attempts the manager fully
+// tears down and reopens a fresh queue and channel set (see Manager.teardown via run), so each
+// attempt runs independently of the last.
+func (m *Manager) RunWithRetry(run func() error, policy RetryPolicy) (err error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		err = m.Run(run)
+		if !policy.shouldRetry(err) {
+			return err
+		}
+
+		if attempt < attempts-1 {
+			time.Sleep(policy.backoff(attempt))
+		}
+	}
+
+	return err
+}
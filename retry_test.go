@@ -0,0 +1,77 @@
+package manager
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffAppliesMultiplierWithoutJitter(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		if got := p.backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitterStaysWithinBounds(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		Multiplier:     1,
+		Jitter:         0.5,
+	}
+
+	base := 100 * time.Millisecond
+	delta := time.Duration(float64(base) * p.Jitter)
+	lo, hi := base-delta, base+delta
+
+	for i := 0; i < 50; i++ {
+		got := p.backoff(0)
+		if got < lo || got > hi {
+			t.Fatalf("backoff() = %v, want within [%v, %v]", got, lo, hi)
+		}
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	transient := stderrors.New("transient")
+	permanent := stderrors.New("permanent")
+
+	classify := func(err error) bool {
+		return err == transient
+	}
+
+	p := RetryPolicy{Classify: classify}
+
+	if p.shouldRetry(nil) {
+		t.Error("shouldRetry(nil) = true, want false")
+	}
+
+	if !p.shouldRetry(transient) {
+		t.Error("shouldRetry(transient) = false, want true")
+	}
+
+	if p.shouldRetry(permanent) {
+		t.Error("shouldRetry(permanent) = true, want false")
+	}
+
+	// A nil Classify should treat every non-nil error as transient
+	var unset RetryPolicy
+	if !unset.shouldRetry(permanent) {
+		t.Error("shouldRetry with nil Classify = false, want true")
+	}
+}
@@ -5,5 +5,7 @@ import (
 	"sync"
 )
 
-// TxnFn is called by the transaction Manager
+// TxnFn is called by the transaction Manager. Implementations should select on ctx.Done()
+// alongside inboundC so a cancelled run or an expired WithTxnTimeout deadline doesn't leave
+// the txn blocked waiting to be signalled.
 type TxnFn func(ctx context.Context, started *sync.WaitGroup, inboundC <-chan error, outboundC chan<- error)
@@ -0,0 +1,12 @@
+package manager
+
+import (
+	"context"
+	"sync"
+)
+
+// TxnFnV2 participates in the Manager's two-phase commit protocol. It must vote on Prepare by
+// sending on voteC (nil to vote yes, a non-nil error to vote no), wait on phaseC for the
+// manager's decision, act on it (commit or roll back), and finally send its outcome back on
+// voteC before returning.
+type TxnFnV2 func(ctx context.Context, started *sync.WaitGroup, phaseC <-chan Phase, voteC chan<- error)